@@ -0,0 +1,316 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// stackEventPollInterval is how often DeployAppWithEvents polls DescribeStackEvents while a
+// deployment is in progress.
+const stackEventPollInterval = 2 * time.Second
+
+// terminal stack-level statuses that end a DeployAppWithEvents poll loop.
+var terminalStackStatuses = map[string]bool{
+	cloudformation.StackStatusCreateComplete:         true,
+	cloudformation.StackStatusUpdateComplete:         true,
+	cloudformation.StackStatusCreateFailed:           true,
+	cloudformation.StackStatusRollbackComplete:       true,
+	cloudformation.StackStatusRollbackFailed:         true,
+	cloudformation.StackStatusUpdateRollbackComplete: true,
+	cloudformation.StackStatusUpdateRollbackFailed:   true,
+}
+
+// failingStackStatuses are the statuses that should surface the first failing resource's reason
+// instead of a generic waiter error.
+var failingStackStatuses = map[string]bool{
+	cloudformation.StackStatusCreateFailed:             true,
+	cloudformation.StackStatusRollbackInProgress:       true,
+	cloudformation.StackStatusUpdateRollbackInProgress: true,
+}
+
+// StackEvent is a single resource lifecycle event within a CloudFormation stack deployment.
+type StackEvent struct {
+	LogicalResourceID    string
+	ResourceStatus       string
+	ResourceStatusReason string
+	Timestamp            time.Time
+}
+
+// DeployApp creates the stack stackName if it doesn't exist, or updates it via a change set
+// named changeSetName otherwise, and blocks until the deployment finishes. An optional
+// DeployOptions customizes capabilities, stack policy, notifications, tags, and the IAM role
+// CloudFormation assumes; callers that don't need any of that can omit it. If the stack already
+// has an operation in progress, DeployApp returns *ErrStackBusy at once without touching it.
+func (cf CloudFormation) DeployApp(template, stackName, changeSetName string, opts ...DeployOptions) error {
+	return cf.deployApp(template, stackName, changeSetName, deployOptionsFrom(opts))
+}
+
+// DeployAppWithEvents creates or updates the stack stackName the same way DeployApp does, but
+// streams StackEvents for each resource transition as they happen instead of blocking silently.
+// The events channel is closed once the deployment reaches a terminal state; the error channel
+// then receives exactly one value (nil on success).
+func (cf CloudFormation) DeployAppWithEvents(template, stackName, changeSetName string, opts ...DeployOptions) (<-chan StackEvent, <-chan error) {
+	events := make(chan StackEvent)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errCh)
+
+		done := make(chan struct{})
+		streamErrCh := make(chan error, 1)
+		go func() {
+			streamErrCh <- cf.streamStackEvents(stackName, events, done)
+		}()
+
+		err := cf.deployApp(template, stackName, changeSetName, deployOptionsFrom(opts))
+		close(done)
+		streamErr := <-streamErrCh
+
+		if err != nil {
+			if streamErr != nil {
+				errCh <- streamErr
+				return
+			}
+			errCh <- err
+			return
+		}
+		errCh <- streamErr
+	}()
+
+	return events, errCh
+}
+
+// deployApp contains the original create-or-update logic, without any progress streaming. It
+// inspects the stack's current status first so it can recover from a previous failed deployment
+// instead of blindly retrying an operation CloudFormation will just reject again.
+func (cf CloudFormation) deployApp(template, stackName, changeSetName string, opts DeployOptions) error {
+	status, err := cf.describeStackStatus(stackName)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status == "":
+		return cf.createApp(template, stackName, opts)
+	case status == cloudformation.StackStatusRollbackComplete:
+		// The stack never had any resources; delete it so CreateStack can start fresh.
+		if _, err := cf.client.DeleteStack(&cloudformation.DeleteStackInput{StackName: aws.String(stackName)}); err != nil {
+			return err
+		}
+		if err := cf.client.WaitUntilStackDeleteComplete(&cloudformation.DescribeStacksInput{StackName: aws.String(stackName)}); err != nil {
+			return err
+		}
+		return cf.createApp(template, stackName, opts)
+	case status == cloudformation.StackStatusUpdateRollbackFailed:
+		if err := cf.RecoverStack(stackName, nil); err != nil {
+			return err
+		}
+		return cf.deployExistingApp(template, stackName, changeSetName, opts)
+	case strings.HasSuffix(status, "_IN_PROGRESS"):
+		return &ErrStackBusy{StackName: stackName, Status: status}
+	default:
+		return cf.deployExistingApp(template, stackName, changeSetName, opts)
+	}
+}
+
+// createApp creates a brand-new stack and waits for it to finish.
+func (cf CloudFormation) createApp(template, stackName string, opts DeployOptions) error {
+	body, url, err := cf.templateSource(stackName, template)
+	if err != nil {
+		return err
+	}
+
+	if _, err := cf.client.CreateStack(&cloudformation.CreateStackInput{
+		StackName:        aws.String(stackName),
+		TemplateBody:     body,
+		TemplateURL:      url,
+		Capabilities:     aws.StringSlice(opts.Capabilities),
+		StackPolicyBody:  stringPtrOrNil(opts.StackPolicyBody),
+		StackPolicyURL:   stringPtrOrNil(opts.StackPolicyURL),
+		NotificationARNs: aws.StringSlice(opts.NotificationARNs),
+		Tags:             tagsFrom(opts.Tags),
+		RoleARN:          stringPtrOrNil(opts.RoleARN),
+	}); err != nil {
+		return err
+	}
+
+	return cf.client.WaitUntilStackCreateComplete(&cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackName),
+	})
+}
+
+func (cf CloudFormation) deployExistingApp(template, stackName, changeSetName string, opts DeployOptions) error {
+	body, url, err := cf.templateSource(stackName, template)
+	if err != nil {
+		return err
+	}
+
+	_, err = cf.client.CreateChangeSet(&cloudformation.CreateChangeSetInput{
+		ChangeSetName:    aws.String(changeSetName),
+		StackName:        aws.String(stackName),
+		TemplateBody:     body,
+		TemplateURL:      url,
+		Capabilities:     aws.StringSlice(opts.Capabilities),
+		NotificationARNs: aws.StringSlice(opts.NotificationARNs),
+		Tags:             tagsFrom(opts.Tags),
+		RoleARN:          stringPtrOrNil(opts.RoleARN),
+		ChangeSetType:    aws.String(cloudformation.ChangeSetTypeUpdate),
+	})
+	if err != nil {
+		return err
+	}
+
+	describeChangeSetInput := &cloudformation.DescribeChangeSetInput{
+		ChangeSetName: aws.String(changeSetName),
+		StackName:     aws.String(stackName),
+	}
+	if err := cf.client.WaitUntilChangeSetCreateComplete(describeChangeSetInput); err != nil {
+		out, descErr := cf.client.DescribeChangeSet(describeChangeSetInput)
+		if descErr != nil {
+			return fmt.Errorf("describe change set: %w", descErr)
+		}
+		if isNoOpChangeSetFailure(aws.StringValue(out.StatusReason)) {
+			// The change set has no changes to apply; nothing left to do.
+			return nil
+		}
+		return err
+	}
+
+	if err := cf.applyStackPolicy(stackName, opts); err != nil {
+		return err
+	}
+
+	if _, err := cf.client.ExecuteChangeSet(&cloudformation.ExecuteChangeSetInput{
+		ChangeSetName: aws.String(changeSetName),
+		StackName:     aws.String(stackName),
+	}); err != nil {
+		return err
+	}
+
+	return cf.client.WaitUntilStackUpdateComplete(&cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackName),
+	})
+}
+
+// streamStackEvents polls DescribeStackEvents for stackName until the stack's own top-level
+// event reaches a terminal status (or done is closed), forwarding each newly seen event to
+// events in chronological order. It tracks the first failing resource's ResourceStatusReason
+// across polls, since the stack-level terminal event is often only observed on a later poll once
+// that failure event has already scrolled past lastSeenEventID, and returns an error built from
+// that reason if the stack ends up in a failure/rollback status.
+//
+// DescribeStackEvents call failures (the stack not existing yet because polling started before
+// deployApp's CreateStack lands, throttling, or anything else) are treated as transient: this is
+// a best-effort observability stream riding alongside deployApp's own waiters, which remain the
+// source of truth for whether the deployment succeeded, so a poll failure here just retries
+// instead of being surfaced as a deploy error.
+func (cf CloudFormation) streamStackEvents(stackName string, events chan<- StackEvent, done <-chan struct{}) error {
+	var lastSeenEventID string
+	var firstFailureReason string
+
+	ticker := time.NewTicker(stackEventPollInterval)
+	defer ticker.Stop()
+
+	for {
+		out, err := cf.client.DescribeStackEvents(&cloudformation.DescribeStackEventsInput{
+			StackName: aws.String(stackName),
+		})
+		if err != nil {
+			select {
+			case <-done:
+				if firstFailureReason != "" {
+					return fmt.Errorf("deploy stack %s: %s", stackName, firstFailureReason)
+				}
+				return nil
+			case <-ticker.C:
+				continue
+			}
+		}
+
+		newEvents, failureReason, terminal := processStackEvents(stackName, out.StackEvents, lastSeenEventID)
+		for _, ev := range newEvents {
+			events <- ev.StackEvent
+			lastSeenEventID = ev.eventID
+		}
+		if firstFailureReason == "" {
+			firstFailureReason = failureReason
+		}
+
+		if terminal {
+			if firstFailureReason != "" {
+				return fmt.Errorf("deploy stack %s: %s", stackName, firstFailureReason)
+			}
+			return nil
+		}
+
+		select {
+		case <-done:
+			if firstFailureReason != "" {
+				return fmt.Errorf("deploy stack %s: %s", stackName, firstFailureReason)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// stackEventWithID pairs a StackEvent with the raw AWS EventId so callers can track the
+// last-seen event without exposing SDK types outside this file.
+type stackEventWithID struct {
+	StackEvent
+	eventID string
+}
+
+// processStackEvents returns, in chronological order, the events from page that come after
+// lastSeenEventID, the ResourceStatusReason of the first failing resource (if any), and whether
+// the stack's own top-level event has reached a terminal status.
+func processStackEvents(stackName string, page []*cloudformation.StackEvent, lastSeenEventID string) ([]stackEventWithID, string, bool) {
+	// DescribeStackEvents returns events most-recent-first; only keep the ones we haven't
+	// emitted yet, then reverse so we emit them oldest-first.
+	var fresh []*cloudformation.StackEvent
+	for _, e := range page {
+		if lastSeenEventID != "" && aws.StringValue(e.EventId) == lastSeenEventID {
+			break
+		}
+		fresh = append(fresh, e)
+	}
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].Timestamp.Before(*fresh[j].Timestamp)
+	})
+
+	var (
+		out           []stackEventWithID
+		failureReason string
+		terminal      bool
+	)
+	for _, e := range fresh {
+		out = append(out, stackEventWithID{
+			StackEvent: StackEvent{
+				LogicalResourceID:    aws.StringValue(e.LogicalResourceId),
+				ResourceStatus:       aws.StringValue(e.ResourceStatus),
+				ResourceStatusReason: aws.StringValue(e.ResourceStatusReason),
+				Timestamp:            aws.TimeValue(e.Timestamp),
+			},
+			eventID: aws.StringValue(e.EventId),
+		})
+
+		if failureReason == "" && failingStackStatuses[aws.StringValue(e.ResourceStatus)] {
+			failureReason = aws.StringValue(e.ResourceStatusReason)
+		}
+
+		if aws.StringValue(e.LogicalResourceId) == stackName && terminalStackStatuses[aws.StringValue(e.ResourceStatus)] {
+			terminal = true
+		}
+	}
+
+	return out, failureReason, terminal
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployAppWithEvents(t *testing.T) {
+	mockTemplate := "mockTemplate"
+	mockStackName := "mockStackName"
+	mockChangeSetName := "mockChangeSetName"
+
+	t1 := time.Unix(100, 0)
+	t2 := time.Unix(200, 0)
+	t3 := time.Unix(300, 0)
+
+	testCases := map[string]struct {
+		events       []*cloudformation.StackEvent
+		wantOrder    []string
+		wantErrMatch string
+	}{
+		"delivers events in chronological order": {
+			// DescribeStackEvents returns newest-first; the oldest event must be emitted first.
+			events: []*cloudformation.StackEvent{
+				{
+					EventId:           aws.String("3"),
+					LogicalResourceId: aws.String(mockStackName),
+					ResourceStatus:    aws.String(cloudformation.StackStatusCreateComplete),
+					Timestamp:         aws.Time(t3),
+				},
+				{
+					EventId:           aws.String("2"),
+					LogicalResourceId: aws.String("WebService"),
+					ResourceStatus:    aws.String(cloudformation.ResourceStatusCreateComplete),
+					Timestamp:         aws.Time(t2),
+				},
+				{
+					EventId:           aws.String("1"),
+					LogicalResourceId: aws.String(mockStackName),
+					ResourceStatus:    aws.String(cloudformation.StackStatusCreateInProgress),
+					Timestamp:         aws.Time(t1),
+				},
+			},
+			wantOrder: []string{mockStackName, "WebService", mockStackName},
+		},
+		"surfaces the first failing resource's reason": {
+			events: []*cloudformation.StackEvent{
+				{
+					EventId:              aws.String("3"),
+					LogicalResourceId:    aws.String(mockStackName),
+					ResourceStatus:       aws.String(cloudformation.StackStatusRollbackInProgress),
+					ResourceStatusReason: aws.String("The following resource(s) failed to create: [WebService]."),
+					Timestamp:            aws.Time(t3),
+				},
+				{
+					EventId:              aws.String("2"),
+					LogicalResourceId:    aws.String("WebService"),
+					ResourceStatus:       aws.String(cloudformation.ResourceStatusCreateFailed),
+					ResourceStatusReason: aws.String("image not found"),
+					Timestamp:            aws.Time(t2),
+				},
+				{
+					EventId:           aws.String("1"),
+					LogicalResourceId: aws.String(mockStackName),
+					ResourceStatus:    aws.String(cloudformation.StackStatusCreateInProgress),
+					Timestamp:         aws.Time(t1),
+				},
+			},
+			wantOrder:    []string{mockStackName, "WebService", mockStackName},
+			wantErrMatch: "image not found",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cf := CloudFormation{
+				client: mockCloudFormation{
+					t: t,
+
+					mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+						return nil, stackDoesNotExistErr(mockStackName)
+					},
+					mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+						return &cloudformation.CreateStackOutput{}, nil
+					},
+					mockWaitUntilStackCreateComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+						return nil
+					},
+					mockDescribeStackEvents: func(t *testing.T, in *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error) {
+						return &cloudformation.DescribeStackEventsOutput{StackEvents: tc.events}, nil
+					},
+				},
+			}
+
+			events, errCh := cf.DeployAppWithEvents(mockTemplate, mockStackName, mockChangeSetName)
+
+			var gotOrder []string
+			for ev := range events {
+				gotOrder = append(gotOrder, ev.LogicalResourceID)
+			}
+			gotErr := <-errCh
+
+			require.Equal(t, tc.wantOrder, gotOrder)
+			if tc.wantErrMatch == "" {
+				require.NoError(t, gotErr)
+			} else {
+				require.Error(t, gotErr)
+				require.Contains(t, gotErr.Error(), tc.wantErrMatch)
+			}
+		})
+	}
+}
@@ -8,11 +8,18 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/stretchr/testify/require"
 )
 
+// stackDoesNotExistErr mimics the error CloudFormation returns from DescribeStacks when no
+// stack with the given name exists.
+func stackDoesNotExistErr(stackName string) error {
+	return awserr.New("ValidationError", fmt.Sprintf("Stack with id %s does not exist", stackName), nil)
+}
+
 func TestDeployApp(t *testing.T) {
 	mockTemplate := "mockTemplate"
 	mockStackName := "mockStackName"
@@ -20,8 +27,12 @@ func TestDeployApp(t *testing.T) {
 	mockError := errors.New("mockError")
 
 	testCases := map[string]struct {
+		mockDescribeStacks                   func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error)
 		mockCreateStack                      func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error)
 		mockWaitUntilStackCreateComplete     func(t *testing.T, in *cloudformation.DescribeStacksInput) error
+		mockDeleteStack                      func(t *testing.T, in *cloudformation.DeleteStackInput) (*cloudformation.DeleteStackOutput, error)
+		mockWaitUntilStackDeleteComplete     func(t *testing.T, in *cloudformation.DescribeStacksInput) error
+		mockContinueUpdateRollback           func(t *testing.T, in *cloudformation.ContinueUpdateRollbackInput) (*cloudformation.ContinueUpdateRollbackOutput, error)
 		mockCreateChangeSet                  func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error)
 		mockWaitUntilChangeSetCreateComplete func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error
 		mockExecuteChangeSet                 func(t *testing.T, in *cloudformation.ExecuteChangeSetInput) (*cloudformation.ExecuteChangeSetOutput, error)
@@ -31,6 +42,12 @@ func TestDeployApp(t *testing.T) {
 		wantErr error
 	}{
 		"should create the stack if one didn't exist already and wait for completion": {
+			mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+				t.Helper()
+
+				require.Equal(t, mockStackName, *in.StackName)
+				return nil, stackDoesNotExistErr(mockStackName)
+			},
 			mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
 				t.Helper()
 
@@ -49,14 +66,14 @@ func TestDeployApp(t *testing.T) {
 			},
 		},
 		"should create and execute change set if stack already exists": {
-			mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+			mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
 				t.Helper()
 
-				require.Equal(t, mockStackName, *in.StackName)
-				require.Equal(t, mockTemplate, *in.TemplateBody)
-				require.Equal(t, cloudformation.CapabilityCapabilityIam, *in.Capabilities[0])
-
-				return nil, awserr.New(cloudformation.ErrCodeAlreadyExistsException, "", nil)
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						{StackStatus: aws.String(cloudformation.StackStatusCreateComplete)},
+					},
+				}, nil
 			},
 			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
 				t.Helper()
@@ -94,14 +111,14 @@ func TestDeployApp(t *testing.T) {
 			},
 		},
 		"should describe stack to check for no changes scenario if WaitUntilChangeSetCreateComplete fails": {
-			mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+			mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
 				t.Helper()
 
-				require.Equal(t, mockStackName, *in.StackName)
-				require.Equal(t, mockTemplate, *in.TemplateBody)
-				require.Equal(t, cloudformation.CapabilityCapabilityIam, *in.Capabilities[0])
-
-				return nil, awserr.New(cloudformation.ErrCodeAlreadyExistsException, "", nil)
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						{StackStatus: aws.String(cloudformation.StackStatusCreateComplete)},
+					},
+				}, nil
 			},
 			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
 				t.Helper()
@@ -129,19 +146,50 @@ func TestDeployApp(t *testing.T) {
 				require.Equal(t, mockStackName, *in.StackName)
 
 				return &cloudformation.DescribeChangeSetOutput{
-					Changes: []*cloudformation.Change{},
+					Changes:      []*cloudformation.Change{},
+					StatusReason: aws.String("The submitted information didn't contain changes. Submit different information to create a change set."),
 				}, nil
 			},
 		},
-		"should wrap DescribeChangeSet error if WaitUntilChangeSetCreateComplete fails": {
-			mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+		"should surface a genuine change set failure even though Changes is empty": {
+			mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
 				t.Helper()
 
-				require.Equal(t, mockStackName, *in.StackName)
-				require.Equal(t, mockTemplate, *in.TemplateBody)
-				require.Equal(t, cloudformation.CapabilityCapabilityIam, *in.Capabilities[0])
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						{StackStatus: aws.String(cloudformation.StackStatusCreateComplete)},
+					},
+				}, nil
+			},
+			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+				t.Helper()
+
+				return &cloudformation.CreateChangeSetOutput{}, nil
+			},
+			mockWaitUntilChangeSetCreateComplete: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error {
+				t.Helper()
+
+				return mockError
+			},
+			mockDescribeChangeSet: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+				t.Helper()
+
+				return &cloudformation.DescribeChangeSetOutput{
+					Changes:      []*cloudformation.Change{},
+					StatusReason: aws.String("Transform AWS::Serverless-2016-10-31 failed with: Invalid Serverless Application Specification."),
+				}, nil
+			},
+			wantErr: mockError,
+		},
+		"should wrap DescribeChangeSet error if WaitUntilChangeSetCreateComplete fails": {
+			mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+				t.Helper()
 
-				return nil, awserr.New(cloudformation.ErrCodeAlreadyExistsException, "", nil)
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						{StackStatus: aws.String(cloudformation.StackStatusCreateComplete)},
+					},
+				}, nil
 			},
 			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
 				t.Helper()
@@ -172,6 +220,93 @@ func TestDeployApp(t *testing.T) {
 			},
 			wantErr: fmt.Errorf("describe change set: %w", mockError),
 		},
+		"should delete and recreate the stack if it's in ROLLBACK_COMPLETE": {
+			mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+				t.Helper()
+
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						{StackStatus: aws.String(cloudformation.StackStatusRollbackComplete)},
+					},
+				}, nil
+			},
+			mockDeleteStack: func(t *testing.T, in *cloudformation.DeleteStackInput) (*cloudformation.DeleteStackOutput, error) {
+				t.Helper()
+
+				require.Equal(t, mockStackName, *in.StackName)
+
+				return &cloudformation.DeleteStackOutput{}, nil
+			},
+			mockWaitUntilStackDeleteComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+				t.Helper()
+
+				require.Equal(t, mockStackName, *in.StackName)
+
+				return nil
+			},
+			mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+				t.Helper()
+
+				require.Equal(t, mockStackName, *in.StackName)
+
+				return &cloudformation.CreateStackOutput{}, nil
+			},
+			mockWaitUntilStackCreateComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+				t.Helper()
+
+				return nil
+			},
+		},
+		"should recover via ContinueUpdateRollback if stack is in UPDATE_ROLLBACK_FAILED": {
+			mockDescribeStacks: func() func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+				calls := 0
+				return func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+					t.Helper()
+					calls++
+
+					status := cloudformation.StackStatusUpdateRollbackComplete
+					if calls == 1 {
+						status = cloudformation.StackStatusUpdateRollbackFailed
+					}
+					return &cloudformation.DescribeStacksOutput{
+						Stacks: []*cloudformation.Stack{{StackStatus: aws.String(status)}},
+					}, nil
+				}
+			}(),
+			mockContinueUpdateRollback: func(t *testing.T, in *cloudformation.ContinueUpdateRollbackInput) (*cloudformation.ContinueUpdateRollbackOutput, error) {
+				t.Helper()
+
+				require.Equal(t, mockStackName, *in.StackName)
+
+				return &cloudformation.ContinueUpdateRollbackOutput{}, nil
+			},
+			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+				t.Helper()
+
+				return &cloudformation.CreateChangeSetOutput{}, nil
+			},
+			mockWaitUntilChangeSetCreateComplete: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error {
+				return nil
+			},
+			mockExecuteChangeSet: func(t *testing.T, in *cloudformation.ExecuteChangeSetInput) (*cloudformation.ExecuteChangeSetOutput, error) {
+				return &cloudformation.ExecuteChangeSetOutput{}, nil
+			},
+			mockWaitUntilStackUpdateComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+				return nil
+			},
+		},
+		"should return ErrStackBusy if the stack has an operation already in progress": {
+			mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+				t.Helper()
+
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []*cloudformation.Stack{
+						{StackStatus: aws.String(cloudformation.StackStatusCreateInProgress)},
+					},
+				}, nil
+			},
+			wantErr: &ErrStackBusy{StackName: mockStackName, Status: cloudformation.StackStatusCreateInProgress},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -180,8 +315,12 @@ func TestDeployApp(t *testing.T) {
 				client: mockCloudFormation{
 					t: t,
 
+					mockDescribeStacks:                   tc.mockDescribeStacks,
 					mockCreateStack:                      tc.mockCreateStack,
 					mockWaitUntilStackCreateComplete:     tc.mockWaitUntilStackCreateComplete,
+					mockDeleteStack:                      tc.mockDeleteStack,
+					mockWaitUntilStackDeleteComplete:     tc.mockWaitUntilStackDeleteComplete,
+					mockContinueUpdateRollback:           tc.mockContinueUpdateRollback,
 					mockCreateChangeSet:                  tc.mockCreateChangeSet,
 					mockWaitUntilChangeSetCreateComplete: tc.mockWaitUntilChangeSetCreateComplete,
 					mockExecuteChangeSet:                 tc.mockExecuteChangeSet,
@@ -195,4 +334,4 @@ func TestDeployApp(t *testing.T) {
 			require.Equal(t, tc.wantErr, gotErr)
 		})
 	}
-}
\ No newline at end of file
+}
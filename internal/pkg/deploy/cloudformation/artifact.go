@@ -0,0 +1,106 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// templateBodyHardLimit is the largest TemplateBody CloudFormation accepts inline; anything over
+// this is rejected outright.
+const templateBodyHardLimit = 51200
+
+// templateSizeThreshold is the rendered template size above which DeployApp packages the
+// template to S3 and passes TemplateURL instead of inlining it as TemplateBody. It sits a few KB
+// below templateBodyHardLimit so templates that grow slightly after this check (for example from
+// CloudFormation's own request overhead) don't get rejected.
+const templateSizeThreshold = templateBodyHardLimit - 4*1024
+
+// ArtifactUploader packages an oversized CloudFormation template so it can be referenced by
+// TemplateURL instead of being inlined as TemplateBody.
+type ArtifactUploader interface {
+	UploadTemplate(stackName, template string) (url string, err error)
+}
+
+// templateSource returns the TemplateBody/TemplateURL pair to set on a CreateStackInput or
+// CreateChangeSetInput for template, uploading it to S3 first via cf.uploader if it's too large
+// to inline.
+func (cf CloudFormation) templateSource(stackName, template string) (body *string, url *string, err error) {
+	if len(template) <= templateSizeThreshold || cf.uploader == nil {
+		return aws.String(template), nil, nil
+	}
+
+	templateURL, err := cf.uploader.UploadTemplate(stackName, template)
+	if err != nil {
+		return nil, nil, fmt.Errorf("upload template for stack %s: %w", stackName, err)
+	}
+	return nil, aws.String(templateURL), nil
+}
+
+// s3ArtifactUploader is the default ArtifactUploader. It lazily creates a region-scoped bootstrap
+// bucket and uploads templates to it with SSE-S3 (AES256) encryption set on each object. Objects
+// are keyed by the stack name and a hash of the template's contents, so uploading the same template
+// for the same stack name again reuses the same key.
+type s3ArtifactUploader struct {
+	client s3iface.S3API
+	region string
+	bucket string
+}
+
+func newS3ArtifactUploader(s *session.Session) *s3ArtifactUploader {
+	region := aws.StringValue(s.Config.Region)
+	return &s3ArtifactUploader{
+		client: s3.New(s),
+		region: region,
+		bucket: fmt.Sprintf("copilot-bootstrap-%s", region),
+	}
+}
+
+// UploadTemplate uploads template to the bootstrap bucket, creating it first if necessary, and
+// returns the TemplateURL CloudFormation can fetch it from.
+func (u *s3ArtifactUploader) UploadTemplate(stackName, template string) (string, error) {
+	if err := u.ensureBucket(); err != nil {
+		return "", fmt.Errorf("ensure bootstrap bucket %s: %w", u.bucket, err)
+	}
+
+	key := fmt.Sprintf("%s/%x.json", stackName, sha256.Sum256([]byte(template)))
+	if _, err := u.client.PutObject(&s3.PutObjectInput{
+		Bucket:               aws.String(u.bucket),
+		Key:                  aws.String(key),
+		Body:                 aws.ReadSeekCloser(strings.NewReader(template)),
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAes256),
+	}); err != nil {
+		return "", fmt.Errorf("upload template to s3://%s/%s: %w", u.bucket, key, err)
+	}
+
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", u.bucket, u.region, key), nil
+}
+
+func (u *s3ArtifactUploader) ensureBucket() error {
+	if _, err := u.client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(u.bucket)}); err == nil {
+		return nil
+	}
+
+	// us-east-1 is the one region that rejects an explicit LocationConstraint matching itself.
+	in := &s3.CreateBucketInput{Bucket: aws.String(u.bucket)}
+	if u.region != "us-east-1" {
+		in.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(u.region),
+		}
+	}
+
+	_, err := u.client.CreateBucket(in)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeBucketAlreadyOwnedByYou {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+type mockArtifactUploader struct {
+	mockUploadTemplate func(stackName, template string) (string, error)
+}
+
+func (m mockArtifactUploader) UploadTemplate(stackName, template string) (string, error) {
+	return m.mockUploadTemplate(stackName, template)
+}
+
+func TestDeployApp_TemplatePackaging(t *testing.T) {
+	mockStackName := "mockStackName"
+	mockChangeSetName := "mockChangeSetName"
+	mockURL := "https://copilot-bootstrap-us-west-2.s3.us-west-2.amazonaws.com/mockStackName/abc.json"
+
+	testCases := map[string]struct {
+		template string
+
+		wantBody *string
+		wantURL  *string
+	}{
+		"small templates are inlined as TemplateBody": {
+			template: "small template",
+			wantBody: aws.String("small template"),
+		},
+		"templates over the threshold are uploaded and referenced by TemplateURL": {
+			template: strings.Repeat("a", templateSizeThreshold+1),
+			wantURL:  aws.String(mockURL),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cf := CloudFormation{
+				client: mockCloudFormation{
+					t: t,
+
+					mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+						return nil, stackDoesNotExistErr(mockStackName)
+					},
+					mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+						t.Helper()
+
+						require.Equal(t, tc.wantBody, in.TemplateBody)
+						require.Equal(t, tc.wantURL, in.TemplateURL)
+						return &cloudformation.CreateStackOutput{}, nil
+					},
+					mockWaitUntilStackCreateComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+						return nil
+					},
+				},
+				uploader: mockArtifactUploader{
+					mockUploadTemplate: func(stackName, template string) (string, error) {
+						require.Equal(t, mockStackName, stackName)
+						return mockURL, nil
+					},
+				},
+			}
+
+			err := cf.DeployApp(tc.template, mockStackName, mockChangeSetName)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestS3ArtifactUploader_UploadTemplate(t *testing.T) {
+	mockStackName := "mockStackName"
+	mockTemplate := "mockTemplate"
+
+	testCases := map[string]struct {
+		region           string
+		mockHeadBucket   func(t *testing.T, in *s3.HeadBucketInput) (*s3.HeadBucketOutput, error)
+		mockCreateBucket func(t *testing.T, in *s3.CreateBucketInput) (*s3.CreateBucketOutput, error)
+	}{
+		"creates the bootstrap bucket with a LocationConstraint outside us-east-1": {
+			region: "us-west-2",
+			mockHeadBucket: func(t *testing.T, in *s3.HeadBucketInput) (*s3.HeadBucketOutput, error) {
+				return nil, errors.New("bucket not found")
+			},
+			mockCreateBucket: func(t *testing.T, in *s3.CreateBucketInput) (*s3.CreateBucketOutput, error) {
+				t.Helper()
+
+				require.Equal(t, "copilot-bootstrap-us-west-2", aws.StringValue(in.Bucket))
+				require.Equal(t, "us-west-2", aws.StringValue(in.CreateBucketConfiguration.LocationConstraint))
+				return &s3.CreateBucketOutput{}, nil
+			},
+		},
+		"omits the LocationConstraint in us-east-1": {
+			region: "us-east-1",
+			mockHeadBucket: func(t *testing.T, in *s3.HeadBucketInput) (*s3.HeadBucketOutput, error) {
+				return nil, errors.New("bucket not found")
+			},
+			mockCreateBucket: func(t *testing.T, in *s3.CreateBucketInput) (*s3.CreateBucketOutput, error) {
+				t.Helper()
+
+				require.Nil(t, in.CreateBucketConfiguration)
+				return &s3.CreateBucketOutput{}, nil
+			},
+		},
+		"skips CreateBucket if the bootstrap bucket already exists": {
+			region: "us-west-2",
+			mockHeadBucket: func(t *testing.T, in *s3.HeadBucketInput) (*s3.HeadBucketOutput, error) {
+				return &s3.HeadBucketOutput{}, nil
+			},
+			mockCreateBucket: func(t *testing.T, in *s3.CreateBucketInput) (*s3.CreateBucketOutput, error) {
+				t.Fatal("CreateBucket should not be called when HeadBucket succeeds")
+				return nil, nil
+			},
+		},
+		"tolerates BucketAlreadyOwnedByYou as a race with another deploy": {
+			region: "us-west-2",
+			mockHeadBucket: func(t *testing.T, in *s3.HeadBucketInput) (*s3.HeadBucketOutput, error) {
+				return nil, errors.New("bucket not found")
+			},
+			mockCreateBucket: func(t *testing.T, in *s3.CreateBucketInput) (*s3.CreateBucketOutput, error) {
+				return nil, awserr.New(s3.ErrCodeBucketAlreadyOwnedByYou, "owned by you", nil)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			u := &s3ArtifactUploader{
+				region: tc.region,
+				bucket: fmt.Sprintf("copilot-bootstrap-%s", tc.region),
+				client: mockS3{
+					t: t,
+
+					mockHeadBucket:   tc.mockHeadBucket,
+					mockCreateBucket: tc.mockCreateBucket,
+					mockPutObject: func(t *testing.T, in *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+						t.Helper()
+
+						require.Equal(t, fmt.Sprintf("copilot-bootstrap-%s", tc.region), aws.StringValue(in.Bucket))
+						require.True(t, strings.HasPrefix(aws.StringValue(in.Key), mockStackName+"/"))
+						require.True(t, strings.HasSuffix(aws.StringValue(in.Key), ".json"))
+						require.Equal(t, s3.ServerSideEncryptionAes256, aws.StringValue(in.ServerSideEncryption))
+						return &s3.PutObjectOutput{}, nil
+					},
+				},
+			}
+
+			url, err := u.UploadTemplate(mockStackName, mockTemplate)
+
+			require.NoError(t, err)
+			require.Contains(t, url, fmt.Sprintf("https://copilot-bootstrap-%s.s3.%s.amazonaws.com/%s/", tc.region, tc.region, mockStackName))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cloudformation provides a client to deploy and manage infrastructure stacks with
+// AWS CloudFormation.
+package cloudformation
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+)
+
+// CloudFormation wraps an AWS CloudFormation client to deploy application stacks.
+type CloudFormation struct {
+	client   cloudformationiface.CloudFormationAPI
+	uploader ArtifactUploader
+}
+
+// New creates a new CloudFormation client from a session.
+func New(s *session.Session) CloudFormation {
+	return CloudFormation{
+		client:   cloudformation.New(s),
+		uploader: newS3ArtifactUploader(s),
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+)
+
+// mockCloudFormation implements cloudformationiface.CloudFormationAPI, delegating only the
+// methods under test to the function fields supplied by each test case.
+type mockCloudFormation struct {
+	cloudformationiface.CloudFormationAPI
+	t *testing.T
+
+	mockCreateStack                      func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error)
+	mockWaitUntilStackCreateComplete     func(t *testing.T, in *cloudformation.DescribeStacksInput) error
+	mockCreateChangeSet                  func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error)
+	mockWaitUntilChangeSetCreateComplete func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error
+	mockExecuteChangeSet                 func(t *testing.T, in *cloudformation.ExecuteChangeSetInput) (*cloudformation.ExecuteChangeSetOutput, error)
+	mockWaitUntilStackUpdateComplete     func(t *testing.T, in *cloudformation.DescribeStacksInput) error
+	mockDescribeChangeSet                func(t *testing.T, in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error)
+	mockDescribeStackEvents              func(t *testing.T, in *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error)
+	mockDescribeStacks                   func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error)
+	mockDeleteStack                      func(t *testing.T, in *cloudformation.DeleteStackInput) (*cloudformation.DeleteStackOutput, error)
+	mockWaitUntilStackDeleteComplete     func(t *testing.T, in *cloudformation.DescribeStacksInput) error
+	mockContinueUpdateRollback           func(t *testing.T, in *cloudformation.ContinueUpdateRollbackInput) (*cloudformation.ContinueUpdateRollbackOutput, error)
+	mockSetStackPolicy                   func(t *testing.T, in *cloudformation.SetStackPolicyInput) (*cloudformation.SetStackPolicyOutput, error)
+}
+
+func (m mockCloudFormation) CreateStack(in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+	return m.mockCreateStack(m.t, in)
+}
+
+func (m mockCloudFormation) WaitUntilStackCreateComplete(in *cloudformation.DescribeStacksInput) error {
+	return m.mockWaitUntilStackCreateComplete(m.t, in)
+}
+
+func (m mockCloudFormation) CreateChangeSet(in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+	return m.mockCreateChangeSet(m.t, in)
+}
+
+func (m mockCloudFormation) WaitUntilChangeSetCreateComplete(in *cloudformation.DescribeChangeSetInput) error {
+	return m.mockWaitUntilChangeSetCreateComplete(m.t, in)
+}
+
+func (m mockCloudFormation) ExecuteChangeSet(in *cloudformation.ExecuteChangeSetInput) (*cloudformation.ExecuteChangeSetOutput, error) {
+	return m.mockExecuteChangeSet(m.t, in)
+}
+
+func (m mockCloudFormation) WaitUntilStackUpdateComplete(in *cloudformation.DescribeStacksInput) error {
+	return m.mockWaitUntilStackUpdateComplete(m.t, in)
+}
+
+func (m mockCloudFormation) DescribeChangeSet(in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+	return m.mockDescribeChangeSet(m.t, in)
+}
+
+func (m mockCloudFormation) DescribeStackEvents(in *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error) {
+	return m.mockDescribeStackEvents(m.t, in)
+}
+
+func (m mockCloudFormation) DescribeStacks(in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+	return m.mockDescribeStacks(m.t, in)
+}
+
+func (m mockCloudFormation) DeleteStack(in *cloudformation.DeleteStackInput) (*cloudformation.DeleteStackOutput, error) {
+	return m.mockDeleteStack(m.t, in)
+}
+
+func (m mockCloudFormation) WaitUntilStackDeleteComplete(in *cloudformation.DescribeStacksInput) error {
+	return m.mockWaitUntilStackDeleteComplete(m.t, in)
+}
+
+func (m mockCloudFormation) ContinueUpdateRollback(in *cloudformation.ContinueUpdateRollbackInput) (*cloudformation.ContinueUpdateRollbackOutput, error) {
+	return m.mockContinueUpdateRollback(m.t, in)
+}
+
+func (m mockCloudFormation) SetStackPolicy(in *cloudformation.SetStackPolicyInput) (*cloudformation.SetStackPolicyOutput, error) {
+	return m.mockSetStackPolicy(m.t, in)
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// mockS3 implements s3iface.S3API, delegating only the methods under test to the function fields
+// supplied by each test case.
+type mockS3 struct {
+	s3iface.S3API
+	t *testing.T
+
+	mockHeadBucket   func(t *testing.T, in *s3.HeadBucketInput) (*s3.HeadBucketOutput, error)
+	mockCreateBucket func(t *testing.T, in *s3.CreateBucketInput) (*s3.CreateBucketOutput, error)
+	mockPutObject    func(t *testing.T, in *s3.PutObjectInput) (*s3.PutObjectOutput, error)
+}
+
+func (m mockS3) HeadBucket(in *s3.HeadBucketInput) (*s3.HeadBucketOutput, error) {
+	return m.mockHeadBucket(m.t, in)
+}
+
+func (m mockS3) CreateBucket(in *s3.CreateBucketInput) (*s3.CreateBucketOutput, error) {
+	return m.mockCreateBucket(m.t, in)
+}
+
+func (m mockS3) PutObject(in *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return m.mockPutObject(m.t, in)
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// DeployOptions customizes how DeployApp creates or updates a stack.
+type DeployOptions struct {
+	// Capabilities to acknowledge, e.g. CAPABILITY_NAMED_IAM or CAPABILITY_AUTO_EXPAND for
+	// templates that use macros or nested transforms. Defaults to [CAPABILITY_IAM].
+	Capabilities []string
+	// StackPolicyBody and StackPolicyURL protect resources from accidental replacement or
+	// deletion during an update; at most one should be set. See DefaultProtectedStackPolicy.
+	StackPolicyBody string
+	StackPolicyURL  string
+	// NotificationARNs receives CloudFormation stack events via SNS.
+	NotificationARNs []string
+	// Tags are applied to every resource CloudFormation supports tagging.
+	Tags map[string]string
+	// RoleARN lets CloudFormation assume a service role for least-privilege deploys instead of
+	// using the caller's own credentials.
+	RoleARN string
+}
+
+// DefaultProtectedStackPolicy denies Update:Replace and Update:Delete on the stateful resource
+// types most likely to hold production data, letting operators opt a deployment into protecting
+// those resources from accidental replacement. Stack policies can only condition on
+// ResourceType, not resource tags, so this can't be scoped any finer than that.
+const DefaultProtectedStackPolicy = `{
+	"Statement": [
+		{
+			"Effect": "Deny",
+			"Principal": "*",
+			"Action": ["Update:Replace", "Update:Delete"],
+			"Resource": "*",
+			"Condition": {
+				"StringEquals": {
+					"ResourceType": [
+						"AWS::RDS::DBInstance",
+						"AWS::RDS::DBCluster",
+						"AWS::DynamoDB::Table",
+						"AWS::S3::Bucket",
+						"AWS::EC2::Volume",
+						"AWS::ElastiCache::CacheCluster",
+						"AWS::ElastiCache::ReplicationGroup"
+					]
+				}
+			}
+		},
+		{
+			"Effect": "Allow",
+			"Principal": "*",
+			"Action": "Update:*",
+			"Resource": "*"
+		}
+	]
+}`
+
+// withDefaults fills in opts' zero-valued fields with DeployApp's defaults.
+func (opts DeployOptions) withDefaults() DeployOptions {
+	if len(opts.Capabilities) == 0 {
+		opts.Capabilities = []string{cloudformation.CapabilityCapabilityIam}
+	}
+	return opts
+}
+
+// deployOptionsFrom returns the first DeployOptions in opts with defaults filled in, or the zero
+// value with defaults filled in if opts is empty. DeployApp and DeployAppWithEvents take opts as
+// a variadic parameter so existing callers don't have to pass one.
+func deployOptionsFrom(opts []DeployOptions) DeployOptions {
+	if len(opts) == 0 {
+		return DeployOptions{}.withDefaults()
+	}
+	return opts[0].withDefaults()
+}
+
+// tagsFrom converts a plain tag map into the SDK's []*Tag shape.
+func tagsFrom(tags map[string]string) []*cloudformation.Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+	out := make([]*cloudformation.Tag, 0, len(tags))
+	for k, v := range tags {
+		out = append(out, &cloudformation.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	return out
+}
+
+// stringPtrOrNil returns nil for an empty string instead of a pointer to it, so optional SDK
+// input fields are left unset rather than explicitly set to "".
+func stringPtrOrNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// applyStackPolicy sets stackName's stack policy from opts, if one was provided. Change sets
+// have no StackPolicyBody/StackPolicyURL field of their own, so an existing stack's policy is
+// applied with a separate SetStackPolicy call before the change set is executed.
+func (cf CloudFormation) applyStackPolicy(stackName string, opts DeployOptions) error {
+	if opts.StackPolicyBody == "" && opts.StackPolicyURL == "" {
+		return nil
+	}
+	_, err := cf.client.SetStackPolicy(&cloudformation.SetStackPolicyInput{
+		StackName:       aws.String(stackName),
+		StackPolicyBody: stringPtrOrNil(opts.StackPolicyBody),
+		StackPolicyURL:  stringPtrOrNil(opts.StackPolicyURL),
+	})
+	return err
+}
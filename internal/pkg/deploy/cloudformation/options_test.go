@@ -0,0 +1,142 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployApp_Options(t *testing.T) {
+	mockTemplate := "mockTemplate"
+	mockStackName := "mockStackName"
+	mockChangeSetName := "mockChangeSetName"
+
+	t.Run("forwards options to CreateStackInput when the stack doesn't exist", func(t *testing.T) {
+		opts := DeployOptions{
+			Capabilities:     []string{cloudformation.CapabilityCapabilityNamedIam},
+			StackPolicyBody:  DefaultProtectedStackPolicy,
+			NotificationARNs: []string{"arn:aws:sns:us-west-2:1234567890:mockTopic"},
+			Tags:             map[string]string{"copilot-application": "mockApp"},
+			RoleARN:          "arn:aws:iam::1234567890:role/mockRole",
+		}
+
+		cf := CloudFormation{
+			client: mockCloudFormation{
+				t: t,
+
+				mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+					return nil, stackDoesNotExistErr(mockStackName)
+				},
+				mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+					t.Helper()
+
+					require.Equal(t, aws.StringSlice(opts.Capabilities), in.Capabilities)
+					require.Equal(t, opts.StackPolicyBody, aws.StringValue(in.StackPolicyBody))
+					require.Equal(t, aws.StringSlice(opts.NotificationARNs), in.NotificationARNs)
+					require.Equal(t, opts.RoleARN, aws.StringValue(in.RoleARN))
+					require.Equal(t, tagsFrom(opts.Tags), in.Tags)
+
+					return &cloudformation.CreateStackOutput{}, nil
+				},
+				mockWaitUntilStackCreateComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+					return nil
+				},
+			},
+		}
+
+		err := cf.DeployApp(mockTemplate, mockStackName, mockChangeSetName, opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("forwards options to CreateChangeSetInput and applies the stack policy before executing", func(t *testing.T) {
+		opts := DeployOptions{
+			StackPolicyBody:  DefaultProtectedStackPolicy,
+			NotificationARNs: []string{"arn:aws:sns:us-west-2:1234567890:mockTopic"},
+			Tags:             map[string]string{"copilot-application": "mockApp"},
+			RoleARN:          "arn:aws:iam::1234567890:role/mockRole",
+		}
+
+		var policySetBeforeExecute bool
+
+		cf := CloudFormation{
+			client: mockCloudFormation{
+				t: t,
+
+				mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+					return &cloudformation.DescribeStacksOutput{
+						Stacks: []*cloudformation.Stack{
+							{StackStatus: aws.String(cloudformation.StackStatusCreateComplete)},
+						},
+					}, nil
+				},
+				mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+					t.Helper()
+
+					require.Equal(t, aws.StringSlice([]string{cloudformation.CapabilityCapabilityIam}), in.Capabilities)
+					require.Equal(t, aws.StringSlice(opts.NotificationARNs), in.NotificationARNs)
+					require.Equal(t, opts.RoleARN, aws.StringValue(in.RoleARN))
+					require.Equal(t, tagsFrom(opts.Tags), in.Tags)
+
+					return &cloudformation.CreateChangeSetOutput{}, nil
+				},
+				mockWaitUntilChangeSetCreateComplete: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error {
+					return nil
+				},
+				mockSetStackPolicy: func(t *testing.T, in *cloudformation.SetStackPolicyInput) (*cloudformation.SetStackPolicyOutput, error) {
+					t.Helper()
+
+					require.Equal(t, mockStackName, *in.StackName)
+					require.Equal(t, opts.StackPolicyBody, aws.StringValue(in.StackPolicyBody))
+					policySetBeforeExecute = true
+
+					return &cloudformation.SetStackPolicyOutput{}, nil
+				},
+				mockExecuteChangeSet: func(t *testing.T, in *cloudformation.ExecuteChangeSetInput) (*cloudformation.ExecuteChangeSetOutput, error) {
+					t.Helper()
+
+					require.True(t, policySetBeforeExecute, "expected stack policy to be set before executing the change set")
+
+					return &cloudformation.ExecuteChangeSetOutput{}, nil
+				},
+				mockWaitUntilStackUpdateComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+					return nil
+				},
+			},
+		}
+
+		err := cf.DeployApp(mockTemplate, mockStackName, mockChangeSetName, opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("defaults to CAPABILITY_IAM when no options are given", func(t *testing.T) {
+		cf := CloudFormation{
+			client: mockCloudFormation{
+				t: t,
+
+				mockDescribeStacks: func(t *testing.T, in *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+					return nil, stackDoesNotExistErr(mockStackName)
+				},
+				mockCreateStack: func(t *testing.T, in *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+					t.Helper()
+
+					require.Equal(t, []*string{aws.String(cloudformation.CapabilityCapabilityIam)}, in.Capabilities)
+					require.Nil(t, in.StackPolicyBody)
+					require.Nil(t, in.RoleARN)
+
+					return &cloudformation.CreateStackOutput{}, nil
+				},
+				mockWaitUntilStackCreateComplete: func(t *testing.T, in *cloudformation.DescribeStacksInput) error {
+					return nil
+				},
+			},
+		}
+
+		err := cf.DeployApp(mockTemplate, mockStackName, mockChangeSetName)
+		require.NoError(t, err)
+	})
+}
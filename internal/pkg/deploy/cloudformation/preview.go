@@ -0,0 +1,110 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// ResourceChange describes a single proposed change within a CloudFormation change set.
+type ResourceChange struct {
+	LogicalResourceID string
+	ResourceType      string
+	Action            string // Add, Modify, or Remove.
+	Replacement       string // True, False, or Conditional.
+	ChangedProperties []string
+}
+
+// PreviewDeployApp creates a change set named changeSetName for stackName without executing it,
+// and returns the list of resource changes it would make. Callers can inspect the result to
+// confirm a deployment (for example a destructive replacement) before calling DeployApp.
+func (cf CloudFormation) PreviewDeployApp(template, stackName, changeSetName string) ([]ResourceChange, error) {
+	body, url, err := cf.templateSource(stackName, template)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &cloudformation.CreateChangeSetInput{
+		ChangeSetName: aws.String(changeSetName),
+		StackName:     aws.String(stackName),
+		TemplateBody:  body,
+		TemplateURL:   url,
+		Capabilities:  aws.StringSlice([]string{cloudformation.CapabilityCapabilityIam}),
+		ChangeSetType: aws.String(cloudformation.ChangeSetTypeCreate),
+	}
+	if _, err := cf.client.CreateChangeSet(in); err != nil {
+		aerr, ok := err.(awserr.Error)
+		if !ok || aerr.Code() != cloudformation.ErrCodeAlreadyExistsException {
+			return nil, err
+		}
+		in.ChangeSetType = aws.String(cloudformation.ChangeSetTypeUpdate)
+		if _, err := cf.client.CreateChangeSet(in); err != nil {
+			return nil, err
+		}
+	}
+
+	describeChangeSetInput := &cloudformation.DescribeChangeSetInput{
+		ChangeSetName: aws.String(changeSetName),
+		StackName:     aws.String(stackName),
+	}
+	if err := cf.client.WaitUntilChangeSetCreateComplete(describeChangeSetInput); err != nil {
+		out, descErr := cf.client.DescribeChangeSet(describeChangeSetInput)
+		if descErr != nil {
+			return nil, fmt.Errorf("describe change set: %w", descErr)
+		}
+		if isNoOpChangeSetFailure(aws.StringValue(out.StatusReason)) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	out, err := cf.client.DescribeChangeSet(describeChangeSetInput)
+	if err != nil {
+		return nil, fmt.Errorf("describe change set: %w", err)
+	}
+	return parseChangeSetChanges(out.Changes), nil
+}
+
+// parseChangeSetChanges converts the raw SDK change set entries into the ResourceChanges that
+// PreviewDeployApp returns. No-op detection, in both PreviewDeployApp and deployExistingApp, goes
+// through isNoOpChangeSetFailure instead.
+func parseChangeSetChanges(changes []*cloudformation.Change) []ResourceChange {
+	var out []ResourceChange
+	for _, change := range changes {
+		rc := change.ResourceChange
+		if rc == nil {
+			continue
+		}
+
+		var changedProperties []string
+		for _, detail := range rc.Details {
+			if detail.Target == nil || detail.Target.Name == nil {
+				continue
+			}
+			changedProperties = append(changedProperties, aws.StringValue(detail.Target.Name))
+		}
+
+		out = append(out, ResourceChange{
+			LogicalResourceID: aws.StringValue(rc.LogicalResourceId),
+			ResourceType:      aws.StringValue(rc.ResourceType),
+			Action:            aws.StringValue(rc.Action),
+			Replacement:       aws.StringValue(rc.Replacement),
+			ChangedProperties: changedProperties,
+		})
+	}
+	return out
+}
+
+// isNoOpChangeSetFailure reports whether a change set's StatusReason indicates CloudFormation
+// rejected it only because it contained no changes, as opposed to a genuine failure that also
+// happens to come back with an empty Changes list.
+func isNoOpChangeSetFailure(statusReason string) bool {
+	return strings.Contains(statusReason, "No updates are to be performed") ||
+		strings.Contains(statusReason, "didn't contain changes")
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewDeployApp(t *testing.T) {
+	mockTemplate := "mockTemplate"
+	mockStackName := "mockStackName"
+	mockChangeSetName := "mockChangeSetName"
+	mockError := errors.New("mockError")
+
+	testCases := map[string]struct {
+		mockCreateChangeSet                  func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error)
+		mockWaitUntilChangeSetCreateComplete func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error
+		mockDescribeChangeSet                func(t *testing.T, in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error)
+
+		wantChanges []ResourceChange
+		wantErr     error
+	}{
+		"previews an Add for a stack that doesn't exist yet": {
+			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+				t.Helper()
+
+				require.Equal(t, cloudformation.ChangeSetTypeCreate, *in.ChangeSetType)
+				return &cloudformation.CreateChangeSetOutput{}, nil
+			},
+			mockWaitUntilChangeSetCreateComplete: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error {
+				return nil
+			},
+			mockDescribeChangeSet: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+				return &cloudformation.DescribeChangeSetOutput{
+					Changes: []*cloudformation.Change{
+						{
+							ResourceChange: &cloudformation.ResourceChange{
+								LogicalResourceId: aws.String("WebService"),
+								ResourceType:      aws.String("AWS::ECS::Service"),
+								Action:            aws.String(cloudformation.ChangeActionAdd),
+							},
+						},
+					},
+				}, nil
+			},
+			wantChanges: []ResourceChange{
+				{LogicalResourceID: "WebService", ResourceType: "AWS::ECS::Service", Action: cloudformation.ChangeActionAdd},
+			},
+		},
+		"previews a Modify and retries as an update change set if the stack already exists": {
+			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+				t.Helper()
+
+				if *in.ChangeSetType == cloudformation.ChangeSetTypeCreate {
+					return nil, awserr.New(cloudformation.ErrCodeAlreadyExistsException, "", nil)
+				}
+				require.Equal(t, cloudformation.ChangeSetTypeUpdate, *in.ChangeSetType)
+				return &cloudformation.CreateChangeSetOutput{}, nil
+			},
+			mockWaitUntilChangeSetCreateComplete: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error {
+				return nil
+			},
+			mockDescribeChangeSet: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+				return &cloudformation.DescribeChangeSetOutput{
+					Changes: []*cloudformation.Change{
+						{
+							ResourceChange: &cloudformation.ResourceChange{
+								LogicalResourceId: aws.String("WebService"),
+								ResourceType:      aws.String("AWS::ECS::Service"),
+								Action:            aws.String(cloudformation.ChangeActionModify),
+								Details: []*cloudformation.ResourceChangeDetail{
+									{Target: &cloudformation.ResourceTargetDefinition{Name: aws.String("DesiredCount")}},
+								},
+							},
+						},
+					},
+				}, nil
+			},
+			wantChanges: []ResourceChange{
+				{LogicalResourceID: "WebService", ResourceType: "AWS::ECS::Service", Action: cloudformation.ChangeActionModify, ChangedProperties: []string{"DesiredCount"}},
+			},
+		},
+		"previews a Modify that requires replacement": {
+			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+				return &cloudformation.CreateChangeSetOutput{}, nil
+			},
+			mockWaitUntilChangeSetCreateComplete: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error {
+				return nil
+			},
+			mockDescribeChangeSet: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+				return &cloudformation.DescribeChangeSetOutput{
+					Changes: []*cloudformation.Change{
+						{
+							ResourceChange: &cloudformation.ResourceChange{
+								LogicalResourceId: aws.String("Database"),
+								ResourceType:      aws.String("AWS::RDS::DBInstance"),
+								Action:            aws.String(cloudformation.ChangeActionModify),
+								Replacement:       aws.String(cloudformation.ReplacementTrue),
+								Details: []*cloudformation.ResourceChangeDetail{
+									{Target: &cloudformation.ResourceTargetDefinition{Name: aws.String("Engine")}},
+								},
+							},
+						},
+					},
+				}, nil
+			},
+			wantChanges: []ResourceChange{
+				{LogicalResourceID: "Database", ResourceType: "AWS::RDS::DBInstance", Action: cloudformation.ChangeActionModify, Replacement: cloudformation.ReplacementTrue, ChangedProperties: []string{"Engine"}},
+			},
+		},
+		"returns no changes when CloudFormation reports the change set has no updates": {
+			mockCreateChangeSet: func(t *testing.T, in *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+				return &cloudformation.CreateChangeSetOutput{}, nil
+			},
+			mockWaitUntilChangeSetCreateComplete: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) error {
+				return mockError
+			},
+			mockDescribeChangeSet: func(t *testing.T, in *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+				return &cloudformation.DescribeChangeSetOutput{
+					Changes:      []*cloudformation.Change{},
+					StatusReason: aws.String("No updates are to be performed."),
+				}, nil
+			},
+			wantChanges: nil,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cf := CloudFormation{
+				client: mockCloudFormation{
+					t: t,
+
+					mockCreateChangeSet:                  tc.mockCreateChangeSet,
+					mockWaitUntilChangeSetCreateComplete: tc.mockWaitUntilChangeSetCreateComplete,
+					mockDescribeChangeSet:                tc.mockDescribeChangeSet,
+				},
+			}
+
+			gotChanges, gotErr := cf.PreviewDeployApp(mockTemplate, mockStackName, mockChangeSetName)
+
+			require.Equal(t, tc.wantErr, gotErr)
+			require.Equal(t, tc.wantChanges, gotChanges)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudformation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// ErrStackBusy means stackName has another operation already in progress, so DeployApp can't
+// safely create or update it until that operation settles.
+type ErrStackBusy struct {
+	StackName string
+	Status    string
+}
+
+func (e *ErrStackBusy) Error() string {
+	return fmt.Sprintf("stack %s has an operation already in progress (status %s)", e.StackName, e.Status)
+}
+
+// RecoverStack brings stackName out of UPDATE_ROLLBACK_FAILED by calling ContinueUpdateRollback
+// and waiting for the stack to reach UPDATE_ROLLBACK_COMPLETE. resourcesToSkip names resources
+// CloudFormation should skip trying to roll back, for the cases it can't do so automatically
+// (for example a resource that was manually deleted out-of-band).
+func (cf CloudFormation) RecoverStack(stackName string, resourcesToSkip []string) error {
+	in := &cloudformation.ContinueUpdateRollbackInput{
+		StackName: aws.String(stackName),
+	}
+	if len(resourcesToSkip) > 0 {
+		in.ResourcesToSkip = aws.StringSlice(resourcesToSkip)
+	}
+
+	if _, err := cf.client.ContinueUpdateRollback(in); err != nil {
+		return err
+	}
+	return cf.waitForStatus(stackName, cloudformation.StackStatusUpdateRollbackComplete)
+}
+
+// describeStackStatus returns the current StackStatus for stackName, or "" if no such stack
+// exists.
+func (cf CloudFormation) describeStackStatus(stackName string) (string, error) {
+	out, err := cf.client.DescribeStacks(&cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && strings.Contains(aerr.Message(), "does not exist") {
+			return "", nil
+		}
+		return "", err
+	}
+	if len(out.Stacks) == 0 {
+		return "", nil
+	}
+	return aws.StringValue(out.Stacks[0].StackStatus), nil
+}
+
+// recoverStackWaitTimeout bounds how long waitForStatus polls before giving up, so a stuck
+// ContinueUpdateRollback can't hang the CLI forever the way the SDK's own bounded waiters don't.
+const recoverStackWaitTimeout = 30 * time.Minute
+
+// waitForStatus polls describeStackStatus until stackName reaches want, returning an error if it
+// instead lands on a different terminal status or doesn't reach want within recoverStackWaitTimeout.
+func (cf CloudFormation) waitForStatus(stackName, want string) error {
+	deadline := time.Now().Add(recoverStackWaitTimeout)
+	for {
+		status, err := cf.describeStackStatus(stackName)
+		if err != nil {
+			return err
+		}
+		if status == want {
+			return nil
+		}
+		if !strings.HasSuffix(status, "_IN_PROGRESS") {
+			return fmt.Errorf("stack %s: expected status %s but got %s", stackName, want, status)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("stack %s: timed out after %s waiting for status %s (last seen %s)", stackName, recoverStackWaitTimeout, want, status)
+		}
+		time.Sleep(stackEventPollInterval)
+	}
+}